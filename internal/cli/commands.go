@@ -0,0 +1,23 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"github.com/mitchellh/cli"
+)
+
+// Commands returns the CLI command factory table consumed by main(), wiring
+// every nomad-pack subcommand defined in this package into the
+// mitchellh/cli command tree. base is the shared baseCommand every
+// subcommand embeds.
+func Commands(base *baseCommand) map[string]cli.CommandFactory {
+	return map[string]cli.CommandFactory{
+		"generate upgrade": func() (cli.Command, error) {
+			return &GenerateUpgradeCommand{baseCommand: base}, nil
+		},
+		"inspect": func() (cli.Command, error) {
+			return &InspectCommand{baseCommand: base}, nil
+		},
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors/packdiags"
+	"github.com/hashicorp/nomad-pack/internal/pkg/variable/parser/upgrade"
+	"github.com/posener/complete"
+)
+
+// GenerateUpgradeCommand rewrites a pack's V1-style variable files and
+// templates into V2 form in place.
+type GenerateUpgradeCommand struct {
+	*baseCommand
+}
+
+func (c *GenerateUpgradeCommand) Run(args []string) int {
+	c.cmdKey = "generate upgrade"
+	flagSet := c.GetFlagSet(flagSetNone)
+	if err := c.parseFlags(args, flagSet); err != nil {
+		return 1
+	}
+
+	args = c.args
+	if len(args) != 1 {
+		c.ui.ErrorWithContext(fmt.Errorf("expected exactly one argument: <pack>"), "invalid arguments")
+		return 1
+	}
+	packPath := args[0]
+
+	sources, err := loadPackSources(packPath)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to read pack sources")
+		return 1
+	}
+
+	upgraded, diags := upgrade.Upgrade(sources)
+	if packdiags.DiagsHasErrors(diags) {
+		c.ui.ErrorWithContext(packdiags.DiagnosticsToError(diags), "failed to upgrade pack")
+		return 1
+	}
+
+	for name, src := range upgraded {
+		if err := os.WriteFile(filepath.Join(packPath, name), src, 0o644); err != nil {
+			c.ui.ErrorWithContext(err, fmt.Sprintf("failed to write %q", name))
+			return 1
+		}
+	}
+
+	c.ui.Success(fmt.Sprintf("Upgraded %d file(s) in %q to V2 variable syntax", len(upgraded), packPath))
+	return 0
+}
+
+// loadPackSources reads every `*.hcl` and `*.nomad.tpl` file under a pack
+// directory, keyed by path relative to that directory, for handing to
+// upgrade.Upgrade.
+func loadPackSources(packPath string) (map[string][]byte, error) {
+	sources := make(map[string][]byte)
+	err := filepath.Walk(packPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if !strings.HasSuffix(path, ".hcl") && !strings.HasSuffix(path, ".nomad.tpl") {
+			return nil
+		}
+		rel, err := filepath.Rel(packPath, path)
+		if err != nil {
+			return err
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sources[rel] = src
+		return nil
+	})
+	return sources, err
+}
+
+func (c *GenerateUpgradeCommand) Help() string {
+	c.Example = `
+	# Upgrade a pack's variable files and templates to V2 syntax in place.
+	nomad-pack generate upgrade ./my-pack
+	`
+	return formatHelp(`
+	Usage: nomad-pack generate upgrade <pack>
+
+	Rewrite a pack's V1-style variable files and templates into V2 form.
+
+` + c.GetExample() + c.Flags().Help())
+}
+
+func (c *GenerateUpgradeCommand) Synopsis() string {
+	return "Upgrade a pack's variable files and templates from V1 to V2 syntax"
+}
+
+func (c *GenerateUpgradeCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictDirs("*")
+}
+
+func (c *GenerateUpgradeCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
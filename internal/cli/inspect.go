@@ -0,0 +1,116 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors/packdiags"
+	"github.com/hashicorp/nomad-pack/internal/pkg/variable/parser"
+	"github.com/posener/complete"
+)
+
+// InspectCommand prints machine-readable metadata about a pack's variables.
+type InspectCommand struct {
+	*baseCommand
+
+	json bool
+	hcl  bool
+}
+
+func (c *InspectCommand) Run(args []string) int {
+	c.cmdKey = "inspect"
+	flagSet := c.GetFlagSet(flagSetOperation)
+	flagSet.BoolVar(&c.json, "json", false, "Output as JSON (default).")
+	flagSet.BoolVar(&c.hcl, "hcl", false, "Output as HCL-ish summary instead of JSON.")
+	if err := c.parseFlags(args, flagSet); err != nil {
+		return 1
+	}
+
+	packRepoName, err := c.extractPackNameFromArgs(c.args)
+	if err != nil {
+		c.ui.ErrorWithContext(err, "invalid arguments")
+		return 1
+	}
+
+	if err := c.parsePackManager(); err != nil {
+		c.ui.ErrorWithContext(err, "failed to set up pack manager")
+		return 1
+	}
+
+	p, err := c.packManager.ProcessTemplate()
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to process pack")
+		return 1
+	}
+
+	inspection, diags := c.parsedVariables.Describe(p)
+	if packdiags.DiagsHasErrors(diags) {
+		c.ui.ErrorWithContext(packdiags.DiagnosticsToError(diags), fmt.Sprintf("failed to inspect %q", packRepoName))
+		return 1
+	}
+
+	if c.hcl {
+		c.ui.Output(renderInspectionAsHCL(inspection))
+		return 0
+	}
+
+	out, err := json.MarshalIndent(inspection, "", "  ")
+	if err != nil {
+		c.ui.ErrorWithContext(err, "failed to marshal inspection")
+		return 1
+	}
+	c.ui.Output(string(out))
+	return 0
+}
+
+// renderInspectionAsHCL renders a PackInspection in a human-skimmable HCL-ish
+// form for `--hcl` output, as an alternative to raw JSON. It recurses into
+// in.Packs so dependency-pack variables get the same coverage the JSON
+// output already has.
+func renderInspectionAsHCL(in *parser.PackInspection) string {
+	return renderInspectionAsHCLR(in, string(in.PackID))
+}
+
+func renderInspectionAsHCLR(in *parser.PackInspection, path string) string {
+	var out string
+	for name, v := range in.Variables {
+		out += fmt.Sprintf("# pack %q\nvariable %q {\n  type        = %s\n  sensitive   = %t\n  source      = %q\n}\n\n", path, name, v.Type, v.Sensitive, v.Source)
+	}
+	for id, dep := range in.Packs {
+		out += renderInspectionAsHCLR(dep, path+"."+string(id))
+	}
+	return out
+}
+
+func (c *InspectCommand) Help() string {
+	c.Example = `
+	# Inspect a pack's variables as JSON.
+	nomad-pack inspect ./my-pack --json
+
+	# Inspect a pack's variables as HCL-ish summary output.
+	nomad-pack inspect ./my-pack --hcl
+	`
+	return formatHelp(`
+	Usage: nomad-pack inspect <pack>
+
+	Print machine-readable metadata about a pack's variables: name, type,
+	default, current value, description, sensitivity, source, and
+	dependency path.
+
+` + c.GetExample() + c.Flags().Help())
+}
+
+func (c *InspectCommand) Synopsis() string {
+	return "Inspect a pack's variables as structured output"
+}
+
+func (c *InspectCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *InspectCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
@@ -0,0 +1,47 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package parser
+
+import "github.com/hashicorp/nomad-pack/sdk/pack"
+
+// CurrentPackKey is the key under which a pack's own PackData is stored in
+// its PackTemplateContext, as opposed to the keys for its dependencies
+// (each stored under its AliasOrName()).
+const CurrentPackKey = "_self"
+
+// PackTemplateContext is the data object passed to a V2 pack template's
+// renderer. It maps CurrentPackKey to the pack's own PackData, and each
+// dependency's AliasOrName() to that dependency's own PackTemplateContext,
+// so a dependency's own PackData sits under CurrentPackKey in that nested
+// context, at `.dep_alias._self`.
+type PackTemplateContext map[string]any
+
+// PackData is the template-facing view of a single pack: its resolved
+// variables (`.var.name`), its resolved locals (`.local.name`), and its
+// metadata (`.nomad_pack.meta`).
+type PackData struct {
+	Pack *pack.Pack
+
+	vars   map[string]any
+	locals map[string]any
+	meta   map[string]any
+}
+
+// Var returns the resolved value of the named variable, backing the
+// `var("name")`/`.var.name` template accessor.
+func (pd PackData) Var(name string) any {
+	return pd.vars[name]
+}
+
+// Local returns the resolved value of the named local, backing the
+// `local("name")`/`.local.name` template accessor.
+func (pd PackData) Local(name string) any {
+	return pd.locals[name]
+}
+
+// Meta returns this pack's metadata map, backing the `.nomad_pack.meta`
+// template accessor.
+func (pd PackData) Meta() map[string]any {
+	return pd.meta
+}
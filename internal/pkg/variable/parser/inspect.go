@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package parser
+
+import (
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/nomad-pack/internal/pkg/errors/packdiags"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// sensitiveRedacted is what a sensitive variable's value/default is
+// replaced with in inspection output, unless revealSensitiveVarsEnvVar is set
+// to "1".
+const sensitiveRedacted = "<sensitive>"
+
+// PackInspection is the machine-readable description of a pack's variables,
+// keyed so downstream tooling (docs generation, CI policy checks, UIs) can
+// drive off of it without re-parsing HCL. Its shape is modeled on
+// terraform-config-inspect's Module/Variable JSON.
+type PackInspection struct {
+	PackID    pack.ID                              `json:"pack_id"`
+	Variables map[variables.ID]*VariableInspection `json:"variables"`
+	Packs     map[pack.ID]*PackInspection          `json:"packs,omitempty"`
+}
+
+// VariableInspection is the per-variable metadata surfaced by `nomad-pack
+// inspect`.
+type VariableInspection struct {
+	Name        variables.ID `json:"name"`
+	Type        string       `json:"type"`
+	Default     any          `json:"default,omitempty"`
+	Value       any          `json:"value,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Sensitive   bool         `json:"sensitive"`
+	Source      string       `json:"source"`
+}
+
+// variable value sources, reported so consumers know whether a value came
+// from its declared default, a varfile, a CLI flag, or the environment.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceCLI     = "cli"
+	SourceEnv     = "env"
+	SourceExec    = "exec"
+	SourceVault   = "vault"
+)
+
+// Describe builds a PackInspection for p and, recursively, for each of its
+// dependencies. It is the V2-only counterpart to ToPackTemplateContext:
+// where that produces a template rendering context, this produces a
+// serializable description of the variables that fed it.
+func (pv *ParsedVariables) Describe(p *pack.Pack) (*PackInspection, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	out := &PackInspection{
+		PackID:    p.ID(),
+		Variables: make(map[variables.ID]*VariableInspection),
+	}
+
+	for id, v := range pv.v2Vars[p.VariablesPath()] {
+		vi, vDiags := describeVariable(id, v)
+		diags = diags.Extend(vDiags)
+		out.Variables[id] = vi
+	}
+
+	deps := p.Dependencies()
+	if len(deps) > 0 {
+		out.Packs = make(map[pack.ID]*PackInspection, len(deps))
+		for _, d := range deps {
+			depInspection, dDiags := pv.Describe(d)
+			diags = diags.Extend(dDiags)
+			out.Packs[d.ID()] = depInspection
+		}
+	}
+
+	return out, diags
+}
+
+// describeVariable converts a single resolved variable into its inspection
+// representation, serializing its cty.Type the same way Terraform's
+// typeexpr package round-trips HCL type expressions.
+func describeVariable(id variables.ID, v *variables.Variable) (*VariableInspection, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	typeStr := typeexpr.TypeString(v.Type)
+
+	value, err := variables.ConvertCtyToInterface(v.Value)
+	if err != nil {
+		diags = packdiags.SafeDiagnosticsAppend(diags, packdiags.DiagFailedToConvertCty(err, v.DeclRange.Ptr()))
+	}
+
+	vi := &VariableInspection{
+		Name:        id,
+		Type:        typeStr,
+		Value:       value,
+		Description: v.Description,
+		Sensitive:   v.Sensitive,
+		Source:      variableSource(v),
+	}
+
+	if v.Default != cty.NilVal {
+		if dv, err := variables.ConvertCtyToInterface(v.Default); err == nil {
+			vi.Default = dv
+		}
+	}
+
+	if vi.Sensitive && os.Getenv(revealSensitiveVarsEnvVar) != "1" {
+		vi.Value = sensitiveRedacted
+		if vi.Default != nil {
+			vi.Default = sensitiveRedacted
+		}
+	}
+
+	return vi, diags
+}
+
+// variableSource reports where a variable's effective value came from.
+// V2 variables track this as they're merged, so this just reads it back;
+// it defaults to SourceDefault for variables that were never overridden.
+func variableSource(v *variables.Variable) string {
+	if v.Source != "" {
+		return v.Source
+	}
+	return SourceDefault
+}
@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package parser
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// TestDescribeVariable golden-tests the JSON shape of a VariableInspection,
+// the unit Describe assembles its PackInspection from.
+func TestDescribeVariable(t *testing.T) {
+	cases := []struct {
+		name   string
+		id     variables.ID
+		v      *variables.Variable
+		golden string
+	}{
+		{
+			name: "default and description",
+			id:   "name",
+			v: &variables.Variable{
+				Type:        cty.String,
+				Value:       cty.StringVal("redis"),
+				Default:     cty.StringVal("app"),
+				Description: "the name to use for the job",
+			},
+			golden: "name.golden.json",
+		},
+		{
+			name: "sensitive",
+			id:   "count",
+			v: &variables.Variable{
+				Type:      cty.Number,
+				Value:     cty.NumberIntVal(3),
+				Sensitive: true,
+			},
+			golden: "count.golden.json",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, diags := describeVariable(tc.id, tc.v)
+			require.False(t, diags.HasErrors())
+
+			gotJSON, err := json.MarshalIndent(got, "", "  ")
+			require.NoError(t, err)
+
+			goldenPath := filepath.Join("testdata", "inspect", tc.golden)
+			if *updateGolden {
+				require.NoError(t, os.MkdirAll(filepath.Dir(goldenPath), 0o755))
+				require.NoError(t, os.WriteFile(goldenPath, gotJSON, 0o644))
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			require.NoError(t, err)
+			require.JSONEq(t, string(want), string(gotJSON))
+		})
+	}
+}
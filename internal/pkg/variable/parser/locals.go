@@ -0,0 +1,211 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package parser
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// localsBlockSchema describes the shape of a pack source file as far as
+// locals parsing cares: zero or more `locals { ... }` blocks, each an
+// arbitrary bag of attributes.
+var localsBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "locals"},
+	},
+}
+
+// ParseV2Locals extracts every `locals { ... }` block declared across a
+// pack's V2 source files and returns the locals declared for each pack,
+// keyed the same way as LoadV2Result's input: by pack.ID and then by
+// local name. Every attribute inside a `locals` block becomes a
+// variables.Variable with Expr set to its (not-yet-evaluated) expression;
+// EvaluateLocals resolves Expr into Value once `var.*` is available.
+func ParseV2Locals(srcs map[pack.ID]hcl.Body) (map[pack.ID]map[variables.ID]*variables.Variable, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+	out := make(map[pack.ID]map[variables.ID]*variables.Variable, len(srcs))
+
+	for id, body := range srcs {
+		content, _, bodyDiags := body.PartialContent(localsBlockSchema)
+		diags = diags.Extend(bodyDiags)
+		if len(content.Blocks) == 0 {
+			continue
+		}
+
+		locals := make(map[variables.ID]*variables.Variable)
+		for _, block := range content.Blocks {
+			attrs, attrDiags := block.Body.JustAttributes()
+			diags = diags.Extend(attrDiags)
+			for name, attr := range attrs {
+				locals[variables.ID(name)] = &variables.Variable{
+					Name:      name,
+					Expr:      attr.Expr,
+					DeclRange: attr.Range,
+				}
+			}
+		}
+		if len(locals) > 0 {
+			out[id] = locals
+		}
+	}
+
+	return out, diags
+}
+
+// ParseV2LocalsFromSource is a convenience wrapper around ParseV2Locals
+// for a single pack's raw HCL source, used by packs with no dependencies
+// and by tests.
+func ParseV2LocalsFromSource(id pack.ID, filename string, src []byte) (map[variables.ID]*variables.Variable, hcl.Diagnostics) {
+	f, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	all, parseDiags := ParseV2Locals(map[pack.ID]hcl.Body{id: f.Body})
+	diags = diags.Extend(parseDiags)
+	return all[id], diags
+}
+
+// LoadV2LocalsResult populates this ParsedVariables with the `locals`
+// blocks found alongside V2 `variable` blocks, as produced by
+// ParseV2Locals. It must be called after LoadV2Result, since locals are
+// only meaningful for V2 packs.
+func (pv *ParsedVariables) LoadV2LocalsResult(in map[pack.ID]map[variables.ID]*variables.Variable) error {
+	if pv.v2Locals != nil {
+		return fmt.Errorf("locals already loaded")
+	}
+	pv.v2Locals = in
+	return nil
+}
+
+// EvaluateLocals resolves every local declared for p, in dependency order,
+// against a scope containing already-resolved `var.*` values and previously
+// evaluated locals. A local that (transitively) references itself produces
+// a diagnostic instead of resolving.
+func (pv *ParsedVariables) EvaluateLocals(p *pack.Pack) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	locals := pv.v2Locals[p.VariablesPath()]
+	if len(locals) == 0 {
+		return diags
+	}
+
+	order, cycleDiags := topoSortLocals(locals)
+	diags = diags.Extend(cycleDiags)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	varVals := make(map[string]cty.Value, len(pv.v2Vars[p.VariablesPath()]))
+	for id, v := range pv.v2Vars[p.VariablesPath()] {
+		varVals[string(id)] = v.Value
+	}
+
+	localVals := make(map[string]cty.Value, len(locals))
+	for _, id := range order {
+		l := locals[id]
+		if l.Expr == nil {
+			continue
+		}
+
+		ctx := &hcl.EvalContext{
+			Variables: map[string]cty.Value{
+				"var":   cty.ObjectVal(varVals),
+				"local": cty.ObjectVal(localVals),
+			},
+		}
+
+		val, valDiags := l.Expr.Value(ctx)
+		diags = diags.Extend(valDiags)
+		if valDiags.HasErrors() {
+			continue
+		}
+
+		l.Value = val
+		localVals[string(id)] = val
+	}
+
+	return diags
+}
+
+// topoSortLocals orders locals so that every local is evaluated after the
+// other locals it depends on, detecting reference cycles along the way.
+func topoSortLocals(locals map[variables.ID]*variables.Variable) ([]variables.ID, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[variables.ID]int, len(locals))
+	var order []variables.ID
+
+	var visit func(id variables.ID) bool
+	visit = func(id variables.ID) bool {
+		switch state[id] {
+		case visited:
+			return true
+		case visiting:
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Circular local reference",
+				Detail:   fmt.Sprintf("local %q is part of a reference cycle", id),
+				Subject:  locals[id].DeclRange.Ptr(),
+			})
+			return false
+		}
+
+		state[id] = visiting
+		for _, dep := range localReferences(locals[id]) {
+			if _, ok := locals[dep]; !ok {
+				continue
+			}
+			if !visit(dep) {
+				return false
+			}
+		}
+		state[id] = visited
+		order = append(order, id)
+		return true
+	}
+
+	for id := range locals {
+		if !visit(id) {
+			return nil, diags
+		}
+	}
+
+	return order, diags
+}
+
+// localReferences returns the names of other locals referenced in l's
+// expression, found by walking its variable traversals for a `local.*`
+// root.
+func localReferences(l *variables.Variable) []variables.ID {
+	if l.Expr == nil {
+		return nil
+	}
+
+	var refs []variables.ID
+	for _, t := range l.Expr.Variables() {
+		if len(t) < 2 {
+			continue
+		}
+		root, ok := t[0].(hcl.TraverseRoot)
+		if !ok || root.Name != "local" {
+			continue
+		}
+		if attr, ok := t[1].(hcl.TraverseAttr); ok {
+			refs = append(refs, variables.ID(attr.Name))
+		}
+	}
+	return refs
+}
@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestParseV2LocalsFromSource(t *testing.T) {
+	src := `
+locals {
+  greeting = "hello"
+  shout    = upper(local.greeting)
+}
+`
+	locals, diags := ParseV2LocalsFromSource("example", "locals.hcl", []byte(src))
+	require.False(t, diags.HasErrors())
+	require.Len(t, locals, 2)
+	require.NotNil(t, locals["greeting"].Expr)
+	require.NotNil(t, locals["shout"].Expr)
+}
+
+func TestLoadV2LocalsResult_EvaluatesParsedLocals(t *testing.T) {
+	src := `
+locals {
+  greeting = "hello ${var.name}"
+}
+`
+	locals, diags := ParseV2LocalsFromSource("example", "locals.hcl", []byte(src))
+	require.False(t, diags.HasErrors())
+
+	pv := &ParsedVariables{}
+	require.NoError(t, pv.LoadV2Result(map[pack.ID]map[variables.ID]*variables.Variable{
+		"example": {
+			"name": {Name: "name", Value: cty.StringVal("world")},
+		},
+	}))
+	require.NoError(t, pv.LoadV2LocalsResult(map[pack.ID]map[variables.ID]*variables.Variable{
+		"example": locals,
+	}))
+
+	p := &pack.Pack{Metadata: &pack.Metadata{PackMeta: &pack.MetadataPack{Name: "example"}}}
+	evalDiags := pv.EvaluateLocals(p)
+	require.False(t, evalDiags.HasErrors())
+	require.Equal(t, "hello world", locals["greeting"].Value.AsString())
+}
+
+func mustParseExpr(t *testing.T, src string) hcl.Expression {
+	t.Helper()
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "test.hcl", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	return expr
+}
+
+func TestTopoSortLocals_OrdersByDependency(t *testing.T) {
+	locals := map[variables.ID]*variables.Variable{
+		"b": {Expr: mustParseExpr(t, "local.a")},
+		"a": {Expr: mustParseExpr(t, `"base"`)},
+		"c": {Expr: mustParseExpr(t, "local.b")},
+	}
+
+	order, diags := topoSortLocals(locals)
+	require.False(t, diags.HasErrors())
+
+	pos := make(map[variables.ID]int, len(order))
+	for i, id := range order {
+		pos[id] = i
+	}
+	require.Less(t, pos["a"], pos["b"])
+	require.Less(t, pos["b"], pos["c"])
+}
+
+func TestTopoSortLocals_DetectsCycle(t *testing.T) {
+	locals := map[variables.ID]*variables.Variable{
+		"a": {Expr: mustParseExpr(t, "local.b"), DeclRange: hcl.Range{}},
+		"b": {Expr: mustParseExpr(t, "local.a"), DeclRange: hcl.Range{}},
+	}
+
+	_, diags := topoSortLocals(locals)
+	require.True(t, diags.HasErrors())
+}
@@ -5,24 +5,61 @@ package parser
 
 import (
 	"errors"
+	"fmt"
+	"os"
 	"slices"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/nomad-pack/internal/pkg/errors/packdiags"
 	"github.com/hashicorp/nomad-pack/internal/pkg/variable/parser/config"
+	nomadpackversion "github.com/hashicorp/nomad-pack/internal/pkg/version"
 	"github.com/hashicorp/nomad-pack/sdk/pack"
 	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
 	"golang.org/x/exp/maps"
 )
 
+// revealSensitiveVarsEnvVar opts a CI run back into seeing real values from
+// AsOverrideFileRedacted, for pipelines that need the actual secret rather
+// than a placeholder.
+const revealSensitiveVarsEnvVar = "NOMAD_PACK_REVEAL_SENSITIVE_VARS"
+
 // ParsedVariables wraps the parsed variables returned by parser.Parse and
 // provides functionality to access them.
 type ParsedVariables struct {
 	v1Vars   map[string]map[string]*variables.Variable
 	v2Vars   map[pack.ID]map[variables.ID]*variables.Variable
+	v2Locals map[pack.ID]map[variables.ID]*variables.Variable
 	Metadata *pack.Metadata
 	version  *config.ParserVersion
+
+	// RunningVersions is checked against every pack's
+	// `required_version`/`required_nomad` constraints in LoadV2Result and
+	// while building the template context. When nil, the running
+	// nomad-pack binary's own version is used and the Nomad version is
+	// treated as unknown (see effectiveRunningVersions), so
+	// `required_version` is still enforced and `required_nomad` is skipped.
+	RunningVersions *RunningVersions
+}
+
+// RunningVersions carries the versions of nomad-pack itself and of the
+// target Nomad cluster, used to validate `required_version`/
+// `required_nomad` constraints declared in a pack's `pack` block.
+type RunningVersions struct {
+	Pack  string
+	Nomad string
+}
+
+// effectiveRunningVersions returns pv.RunningVersions, or a default built
+// from the running nomad-pack binary's own version if the caller never set
+// one. The Nomad version is left blank in that default, since nothing but
+// an explicit caller with a live Nomad client can know it; checkConstraint
+// treats a blank actual version as "unknown, skip".
+func (pv *ParsedVariables) effectiveRunningVersions() RunningVersions {
+	if pv.RunningVersions != nil {
+		return *pv.RunningVersions
+	}
+	return RunningVersions{Pack: nomadpackversion.Version}
 }
 
 func (pv *ParsedVariables) IsV2() bool {
@@ -53,13 +90,42 @@ func (pv *ParsedVariables) LoadV1Result(in map[string]map[string]*variables.Vari
 // LoadV2Result populates this ParsedVariables with the result from
 // parser_v2.Parse(). This function errors if the ParsedVariable has already
 // been loaded.
+//
+// If pv.Metadata is set (the root pack's metadata, decoded via
+// pack.Metadata.DecodeRequiredVersions), its `required_version`/
+// `required_nomad` constraints are validated here, at parse time, against
+// pv.effectiveRunningVersions(). Cross-dependency constraints - a
+// dependency pack declaring a stricter requirement than its parent - are
+// re-checked later, once the full pack tree is available, by
+// CheckRequiredVersions/ToPackTemplateContext.
+//
+// Once loaded, ResolveValueSources runs immediately, so any variable with
+// a `source` annotation and no value from a higher-precedence CLI flag or
+// varfile is resolved before this function returns.
 func (pv *ParsedVariables) LoadV2Result(in map[pack.ID]map[variables.ID]*variables.Variable) error {
 	if pv.isLoaded() {
 		return errors.New("already loaded")
 	}
+
+	if pv.Metadata != nil {
+		rv := pv.effectiveRunningVersions()
+		root := &pack.Pack{Metadata: pv.Metadata}
+		if diags := checkConstraint(root, "nomad-pack", rv.Pack, pv.Metadata.RequiredVersion()); diags.HasErrors() {
+			return packdiags.DiagnosticsToError(diags)
+		}
+		if diags := checkConstraint(root, "Nomad", rv.Nomad, pv.Metadata.RequiredNomad()); diags.HasErrors() {
+			return packdiags.DiagnosticsToError(diags)
+		}
+	}
+
 	var vPtr = config.V2
 	pv.v2Vars = maps.Clone(in)
 	pv.version = &vPtr
+
+	if diags := pv.ResolveValueSources(); diags.HasErrors() {
+		return packdiags.DiagnosticsToError(diags)
+	}
+
 	return nil
 }
 
@@ -100,29 +166,53 @@ func asV2Vars(in map[string]map[string]*variables.Variable) map[pack.ID]map[vari
 // Even though parsing the variable went without error, it is highly
 // possible that conversion to native go types can incur an error.
 // If an error is returned, it should be considered terminal.
+// Sensitive variables are passed through at their real value here; they are
+// only redacted in generated varfiles and diagnostic rendering, since the
+// renderer needs the actual secret to produce a correct job spec.
 func (pv *ParsedVariables) ToPackTemplateContext(p *pack.Pack) (PackTemplateContext, hcl.Diagnostics) {
 	out := make(PackTemplateContext)
 	diags := pv.toPackTemplateContextR(&out, p)
 	return out, diags
 }
 
-// toPackTemplateContextR is the recursive implementation of ToPackTemplateContext
+// toPackTemplateContextR is the recursive implementation of
+// ToPackTemplateContext. It checks only p's own required_version/
+// required_nomad constraint (not p's whole dependency subtree, unlike
+// CheckRequiredVersions) since the loop below already recurses into each
+// dependency via toPackTemplateContextR itself - calling the
+// subtree-recursing check here too would re-validate every dependency once
+// per ancestor.
 func (pv *ParsedVariables) toPackTemplateContextR(tgt *PackTemplateContext, p *pack.Pack) hcl.Diagnostics {
-	pVars, diags := asMapOfStringToAny(pv.v2Vars[p.VariablesPath()])
+	rv := pv.effectiveRunningVersions()
+	diags := checkOwnRequiredVersions(p, rv.Pack, rv.Nomad)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	pVars, vDiags := asMapOfStringToAny(pv.v2Vars[p.VariablesPath()])
+	diags = diags.Extend(vDiags)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	diags = diags.Extend(pv.EvaluateLocals(p))
 	if diags.HasErrors() {
 		return diags
 	}
+	pLocals, lDiags := asMapOfStringToAny(pv.v2Locals[p.VariablesPath()])
+	diags = diags.Extend(lDiags)
 
 	(*tgt)[CurrentPackKey] = PackData{
-		Pack: p,
-		vars: pVars,
-		meta: p.Metadata.ConvertToMapInterface(),
+		Pack:   p,
+		vars:   pVars,
+		locals: pLocals,
+		meta:   p.Metadata.ConvertToMapInterface(),
 	}
 
 	for _, d := range p.Dependencies() {
 		out := make(PackTemplateContext)
 		diags.Extend(pv.toPackTemplateContextR(&out, d))
-		(*tgt)[d.AliasOrName()] = out
+		(*tgt)[string(d.AliasOrName())] = out
 	}
 
 	return diags
@@ -146,7 +236,9 @@ func asMapOfStringToAny(m map[variables.ID]*variables.Variable) (map[string]any,
 // SECTION: ParserV1 helper functions
 
 // ConvertVariablesToMapInterface creates the data object for V1 syntax
-// templates.
+// templates. As with ToPackTemplateContext, sensitive variables are passed
+// through at their real value so rendering still works; redaction only
+// applies to generated varfiles and diagnostics.
 func (pv *ParsedVariables) ConvertVariablesToMapInterface() (map[string]any, hcl.Diagnostics) {
 
 	// Create our output; no matter what we return something.
@@ -183,8 +275,27 @@ func (pv *ParsedVariables) ConvertVariablesToMapInterface() (map[string]any, hcl
 // SECTION: Generator helper functions
 
 // AsOverrideFile formats a ParsedVariables so it can be used as a var-file.
-// This is used in the `generate varfile` command.
+// This is used in the `generate varfile` command. Sensitive variables are
+// rendered at their real value; use AsOverrideFileRedacted for a shareable
+// varfile.
 func (pv *ParsedVariables) AsOverrideFile() string {
+	return pv.asOverrideFile(false)
+}
+
+// AsOverrideFileRedacted is identical to AsOverrideFile, except that any
+// variable declared `sensitive = true` is rendered as `<sensitive>` with an
+// explanatory comment instead of its real value. This is what `generate
+// varfile` uses by default, so a varfile can be safely committed or shared.
+// Setting the NOMAD_PACK_REVEAL_SENSITIVE_VARS environment variable to "1"
+// disables redaction, for CI pipelines that need the real values.
+func (pv *ParsedVariables) AsOverrideFileRedacted() string {
+	if os.Getenv(revealSensitiveVarsEnvVar) == "1" {
+		return pv.asOverrideFile(false)
+	}
+	return pv.asOverrideFile(true)
+}
+
+func (pv *ParsedVariables) asOverrideFile(redact bool) string {
 	var out strings.Builder
 	out.WriteString(pv.varFileHeader())
 
@@ -197,6 +308,10 @@ func (pv *ParsedVariables) AsOverrideFile() string {
 		slices.Sort(varnames)
 		for _, varname := range varnames {
 			v := vs[varname]
+			if redact && v.Sensitive {
+				out.WriteString(redactedOverrideString(varname, packname))
+				continue
+			}
 			out.WriteString(v.AsOverrideString(packname))
 		}
 	}
@@ -204,6 +319,13 @@ func (pv *ParsedVariables) AsOverrideFile() string {
 	return out.String()
 }
 
+// redactedOverrideString renders the override line for a sensitive
+// variable without its value, mirroring the line shape produced by
+// variables.Variable.AsOverrideString.
+func redactedOverrideString(name variables.ID, packname pack.ID) string {
+	return fmt.Sprintf("%s.%s = \"<sensitive>\" # redacted; set %s=1 to reveal\n", packname, name, revealSensitiveVarsEnvVar)
+}
+
 // varFileHeader provides additional content to be placed at the top of a
 // generated varfile
 func (pv *ParsedVariables) varFileHeader() string {
@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad-pack/internal/pkg/variable/parser/config"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func testParsedVariables(t *testing.T) *ParsedVariables {
+	t.Helper()
+	vPtr := config.V2
+	return &ParsedVariables{
+		v2Vars: map[pack.ID]map[variables.ID]*variables.Variable{
+			"foo": {
+				"token": {
+					Name:      "token",
+					Type:      cty.String,
+					Value:     cty.StringVal("shh"),
+					Sensitive: true,
+				},
+				"name": {
+					Name:  "name",
+					Type:  cty.String,
+					Value: cty.StringVal("redis"),
+				},
+			},
+		},
+		version: &vPtr,
+	}
+}
+
+func TestAsOverrideFileRedacted(t *testing.T) {
+	pv := testParsedVariables(t)
+
+	redacted := pv.AsOverrideFileRedacted()
+	require.Contains(t, redacted, sensitiveRedacted)
+	require.NotContains(t, redacted, "shh")
+	require.Contains(t, redacted, "redis")
+}
+
+func TestAsOverrideFileRedacted_RevealEnvVar(t *testing.T) {
+	t.Setenv(revealSensitiveVarsEnvVar, "1")
+	pv := testParsedVariables(t)
+
+	revealed := pv.AsOverrideFileRedacted()
+	require.Contains(t, revealed, "shh")
+	require.False(t, strings.Contains(revealed, sensitiveRedacted))
+}
+
+func TestAsOverrideFile_NeverRedacts(t *testing.T) {
+	require.NoError(t, os.Unsetenv(revealSensitiveVarsEnvVar))
+	pv := testParsedVariables(t)
+
+	full := pv.AsOverrideFile()
+	require.Contains(t, full, "shh")
+}
+
+func TestToPackTemplateContext_NilMetadataDoesNotPanic(t *testing.T) {
+	// A pack.Pack without a `pack { ... }` block has a nil Metadata - valid
+	// input per pack.Pack.ID - so rendering it must not panic.
+	vPtr := config.V2
+	pv := &ParsedVariables{
+		v2Vars:  map[pack.ID]map[variables.ID]*variables.Variable{},
+		version: &vPtr,
+	}
+	p := &pack.Pack{}
+
+	require.NotPanics(t, func() {
+		_, diags := pv.ToPackTemplateContext(p)
+		require.False(t, diags.HasErrors())
+	})
+}
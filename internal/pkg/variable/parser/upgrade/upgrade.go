@@ -0,0 +1,401 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package upgrade implements an automatic source-to-source rewriter that
+// turns V1-style pack variable files and templates into their V2
+// equivalents. It is modeled on Terraform's configupgrade package: callers
+// hand it a set of named sources, and it hands back the rewritten sources
+// alongside any diagnostics it could not resolve on its own.
+package upgrade
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"golang.org/x/exp/maps"
+)
+
+// upgradedHeader is prepended to every file this package rewrites so readers
+// know the V2 syntax below was generated rather than hand-written.
+const upgradedHeader = "# This file was automatically upgraded from nomad-pack V1 variable syntax.\n# Review the rewritten references below before committing them.\n\n"
+
+// v1Ref is a single `<pack>.<var>` style reference found while scanning a
+// V1 source file or template, along with any deeper selector (e.g. the
+// ".sub" in `.pack.var.sub`) that follows the variable name.
+type v1Ref struct {
+	PackName string
+	VarName  string
+	Suffix   string
+	Range    hcl.Range
+}
+
+// dependencyGraph maps a pack name, as addressed in V1 sources, to the set
+// of V2 dependency paths it could plausibly resolve to. Ambiguous entries
+// (len > 1) are reported as diagnostics rather than guessed at.
+type dependencyGraph map[string][]string
+
+// Upgrade rewrites the given sources, keyed by filename, from V1 pack
+// variable syntax into V2 syntax. `*.hcl` files are treated as variable
+// declarations or override files; `*.nomad.tpl` files are treated as
+// templates and have their `{{ .pack_name.var_name }}`-style references
+// rewritten to `{{ var "var_name" "pack.path" }}` / PackTemplateContext
+// accessor form.
+//
+// Ambiguous V1 references - a pack name that maps to more than one V2
+// dependency path - are surfaced as diagnostics rather than silently
+// resolved, since guessing wrong would silently change behavior.
+func Upgrade(sources map[string][]byte) (map[string][]byte, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	graph, graphDiags := buildDependencyGraph(sources)
+	diags = diags.Extend(graphDiags)
+
+	out := make(map[string][]byte, len(sources))
+	for name, src := range sources {
+		switch {
+		case strings.HasSuffix(name, ".nomad.tpl"):
+			rewritten, fDiags := upgradeTemplate(name, src, graph)
+			diags = diags.Extend(fDiags)
+			out[name] = rewritten
+		case strings.HasSuffix(name, ".hcl"):
+			rewritten, fDiags := upgradeHCL(name, src, graph)
+			diags = diags.Extend(fDiags)
+			out[name] = rewritten
+		default:
+			// Leave anything we don't recognise untouched.
+			out[name] = src
+		}
+	}
+
+	return out, diags
+}
+
+// buildDependencyGraph scans every `*.hcl` source for `variable` blocks and
+// records, for each pack name a V1 source could address (the last path
+// component of its directory), every dotted V2 dependency path at which a
+// pack by that name actually appears. A pack name that shows up under more
+// than one directory path - e.g. both "deps/a/b/variables.hcl" and
+// "deps/c/b/variables.hcl" declaring pack "b" - is ambiguous and recorded
+// with len(paths) > 1, so callers refuse to guess which one a V1 reference
+// meant.
+//
+// Override/varfiles - flat `pack.var = value` attributes, which aren't
+// valid native HCL syntax since an attribute name can't contain a "." -
+// fail to parse here and are silently skipped; they declare no `variable`
+// blocks and so contribute nothing to the graph anyway.
+func buildDependencyGraph(sources map[string][]byte) (dependencyGraph, hcl.Diagnostics) {
+	graph := make(dependencyGraph)
+	parser := hclparse.NewParser()
+	rootName := rootPackMetadataName(sources)
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".hcl") {
+			continue
+		}
+		f, pDiags := parser.ParseHCL(sources[name], name)
+		if pDiags.HasErrors() || f == nil {
+			continue
+		}
+
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type != "variable" || len(block.Labels) != 1 {
+				continue
+			}
+			packName, path := dependencyPath(name, rootName)
+			graph[packName] = appendUnique(graph[packName], path)
+		}
+	}
+
+	return graph, nil
+}
+
+// rootPackMetadataName scans sources for a root-level `pack { name = "..." }`
+// metadata block - the one place a pack's real declared name lives - and
+// returns the name it declares, or "" if none is found. "Root-level" means
+// the file has no directory component; a dependency pack's own metadata
+// file describes that dependency, not the root.
+func rootPackMetadataName(sources map[string][]byte) string {
+	parser := hclparse.NewParser()
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".hcl") || strings.Contains(name, "/") {
+			continue
+		}
+		f, pDiags := parser.ParseHCL(sources[name], name)
+		if pDiags.HasErrors() || f == nil {
+			continue
+		}
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type != "pack" {
+				continue
+			}
+			attrs, attrDiags := block.Body.JustAttributes()
+			if attrDiags.HasErrors() {
+				continue
+			}
+			attr, ok := attrs["name"]
+			if !ok {
+				continue
+			}
+			var raw string
+			if diags := gohcl.DecodeExpression(attr.Expr, nil, &raw); !diags.HasErrors() && raw != "" {
+				return raw
+			}
+		}
+	}
+	return ""
+}
+
+// dependencyPath derives a source file's addressable pack name and its
+// dotted V2 dependency path (every directory component joined with ".")
+// from its path relative to the pack root, e.g.
+// "deps/foo/bar/variables.hcl" -> ("bar", "deps.foo.bar"). A file at the pack
+// root, with no intervening directories, addresses the root pack itself,
+// under rootName - its real declared name from rootPackMetadataName, or a
+// best-effort filename-derived fallback if the root never declared one -
+// so a dependency pack that happens to share the root's real name is
+// recorded as a genuine collision rather than silently missed.
+func dependencyPath(file, rootName string) (name, path string) {
+	dir := strings.TrimSuffix(file, "/"+pathBase(file))
+	if dir == file {
+		dir = ""
+	}
+	parts := strings.Split(dir, "/")
+	if dir == "" || (len(parts) == 1 && parts[0] == "") {
+		if rootName == "" {
+			rootName = fallbackRootPackName(file)
+		}
+		return rootName, rootName
+	}
+	return parts[len(parts)-1], strings.Join(parts, ".")
+}
+
+func pathBase(file string) string {
+	idx := strings.LastIndex(file, "/")
+	if idx < 0 {
+		return file
+	}
+	return file[idx+1:]
+}
+
+// fallbackRootPackName derives a best-effort addressable name for the root
+// pack from its source file's own path, for use only when the root pack's
+// sources never declared a real name via a `pack { name = ... }` block.
+func fallbackRootPackName(path string) string {
+	parts := strings.Split(strings.TrimSuffix(path, "/variables.hcl"), "/")
+	return parts[len(parts)-1]
+}
+
+func appendUnique(in []string, v string) []string {
+	for _, existing := range in {
+		if existing == v {
+			return in
+		}
+	}
+	return append(in, v)
+}
+
+// v1OverrideLinePattern matches a single `pack.var = value` line from a V1
+// override/varfile (the format parser.ParsedVariables.AsOverrideFile
+// produces). This isn't valid native HCL - an attribute name can't contain
+// a "." - so override files are scanned line by line rather than parsed as
+// HCL; a genuine V1 `variable "name" { ... }` declarations file, which is
+// valid HCL, is left to the hclsyntax path below instead.
+var v1OverrideLinePattern = regexp.MustCompile(`(?m)^\s*([A-Za-z_][\w-]*)\.([A-Za-z_][\w-]*)\s*=\s*(.+?)\s*$`)
+
+// upgradeHCL rewrites a single V1 source file into V2 form. A `variable
+// "name" { ... }` declarations file is already valid V2 syntax and is
+// carried over untouched (besides the upgraded-file header). A V1
+// override/varfile's flat `pack.var = value` attributes are regrouped into
+// nested V2 `pack "name" { var = value }` blocks.
+func upgradeHCL(name string, src []byte, graph dependencyGraph) ([]byte, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	parser := hclparse.NewParser()
+	f, pDiags := parser.ParseHCL(src, name)
+	if !pDiags.HasErrors() && f != nil {
+		if _, ok := f.Body.(*hclsyntax.Body); ok {
+			return []byte(upgradedHeader + string(src)), diags
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString(upgradedHeader)
+
+	byPack := make(map[string]map[string]string)
+	for _, m := range v1OverrideLinePattern.FindAllStringSubmatch(string(src), -1) {
+		packName, varName, value := m[1], m[2], m[3]
+		if byPack[packName] == nil {
+			byPack[packName] = make(map[string]string)
+		}
+		byPack[packName][varName] = value
+	}
+
+	for _, line := range strings.Split(string(src), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		if !v1OverrideLinePattern.MatchString(line) {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagWarning,
+				Summary:  "Unrecognized V1 override line",
+				Detail:   fmt.Sprintf("%q is not a dotted pack.var assignment and was left out of the upgrade.", trimmed),
+			})
+		}
+	}
+
+	packNames := maps.Keys(byPack)
+	sort.Strings(packNames)
+	for _, packName := range packNames {
+		paths, ambiguous := graph[packName]
+		path := packName
+		if ambiguous && len(paths) == 1 {
+			path = paths[0]
+		} else if ambiguous && len(paths) > 1 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Ambiguous V1 pack reference",
+				Detail: fmt.Sprintf(
+					"%q resolves to multiple V2 dependency paths (%s); rewrite it by hand to the intended one.",
+					packName, strings.Join(paths, ", "),
+				),
+			})
+			continue
+		}
+
+		out.WriteString(fmt.Sprintf("pack %q {\n", path))
+		varNames := maps.Keys(byPack[packName])
+		sort.Strings(varNames)
+		for _, varName := range varNames {
+			out.WriteString(fmt.Sprintf("  %s = %s\n", varName, byPack[packName][varName]))
+		}
+		out.WriteString("}\n")
+	}
+
+	return []byte(out.String()), diags
+}
+
+// v1TemplateRefPattern matches a `{{ .pack_name.var_name }}` style
+// reference, optionally followed by deeper selectors (e.g.
+// `.pack_name.var_name.sub_field`), scoped to the `{{ }}` action itself so
+// it never matches text inside comments or string literals elsewhere in
+// the template.
+var v1TemplateRefPattern = regexp.MustCompile(`\{\{\s*\.([A-Za-z_][A-Za-z0-9_]*)\.([A-Za-z_][A-Za-z0-9_]*)((?:\.[A-Za-z_][A-Za-z0-9_]*)*)\s*\}\}`)
+
+// upgradeTemplate rewrites `{{ .pack.var }}` style references in a V1
+// `*.nomad.tpl` template to the V2 `{{ var "var" "pack.path" }}` /
+// PackTemplateContext accessor form. A deeper selector on the reference
+// (e.g. `.pack.var.sub`) is preserved as a trailing field access on the
+// rewritten expression.
+func upgradeTemplate(name string, src []byte, graph dependencyGraph) ([]byte, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	refs := findV1TemplateRefs(name, string(src))
+
+	var out strings.Builder
+	last := 0
+	text := string(src)
+	for _, ref := range refs {
+		paths, ambiguous := graph[ref.PackName]
+		if ambiguous && len(paths) > 1 {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Ambiguous V1 variable reference",
+				Detail: fmt.Sprintf(
+					"%q resolves to multiple V2 dependency paths (%s); rewrite it by hand to the intended one.",
+					ref.PackName+"."+ref.VarName, strings.Join(paths, ", "),
+				),
+				Subject: &ref.Range,
+			})
+			continue
+		}
+
+		path := ref.PackName
+		if len(paths) == 1 {
+			path = paths[0]
+		}
+
+		out.WriteString(text[last:ref.Range.Start.Byte])
+		if ref.Suffix == "" {
+			out.WriteString(fmt.Sprintf(`{{ var %q %q }}`, ref.VarName, path))
+		} else {
+			out.WriteString(fmt.Sprintf(`{{ (var %q %q)%s }}`, ref.VarName, path, ref.Suffix))
+		}
+		last = ref.Range.End.Byte
+	}
+	out.WriteString(text[last:])
+
+	return []byte(upgradedHeader + out.String()), diags
+}
+
+// findV1TemplateRefs locates `{{ .pack_name.var_name[.more] }}` style
+// template actions in a template body using v1TemplateRefPattern, so only
+// genuine template actions are matched rather than any dotted text that
+// happens to appear in a comment or string literal.
+func findV1TemplateRefs(filename, src string) []v1Ref {
+	matches := v1TemplateRefPattern.FindAllStringSubmatchIndex(src, -1)
+	if matches == nil {
+		return nil
+	}
+
+	refs := make([]v1Ref, 0, len(matches))
+	for _, m := range matches {
+		refs = append(refs, v1Ref{
+			PackName: src[m[2]:m[3]],
+			VarName:  src[m[4]:m[5]],
+			Suffix:   src[m[6]:m[7]],
+			Range:    byteRangeToHCLRange(filename, src, m[0], m[1]),
+		})
+	}
+	return refs
+}
+
+// byteRangeToHCLRange converts a pair of byte offsets into src to an
+// hcl.Range, for attaching diagnostics to a regex-located match.
+func byteRangeToHCLRange(filename, src string, startByte, endByte int) hcl.Range {
+	return hcl.Range{
+		Filename: filename,
+		Start:    byteOffsetToPos(src, startByte),
+		End:      byteOffsetToPos(src, endByte),
+	}
+}
+
+func byteOffsetToPos(src string, offset int) hcl.Pos {
+	line, col := 1, 1
+	for i := 0; i < offset && i < len(src); i++ {
+		if src[i] == '\n' {
+			line++
+			col = 1
+			continue
+		}
+		col++
+	}
+	return hcl.Pos{Line: line, Column: col, Byte: offset}
+}
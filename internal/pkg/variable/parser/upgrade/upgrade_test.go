@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package upgrade
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgrade_HCLOverrideFile(t *testing.T) {
+	sources := map[string][]byte{
+		"variables.hcl": []byte(`example.greeting = "hello"
+example.count = 3
+`),
+	}
+
+	out, diags := Upgrade(sources)
+	require.False(t, diags.HasErrors())
+
+	got := string(out["variables.hcl"])
+	require.Contains(t, got, `pack "example" {`)
+	require.Contains(t, got, `count = 3`)
+	require.Contains(t, got, `greeting = "hello"`)
+}
+
+func TestUpgrade_AmbiguousDependencyIsReported(t *testing.T) {
+	sources := map[string][]byte{
+		"deps/a/b/variables.hcl": []byte(`variable "thing" {
+  type = string
+}
+`),
+		"deps/c/b/variables.hcl": []byte(`variable "thing" {
+  type = string
+}
+`),
+		"template.nomad.tpl": []byte(`{{ .b.thing }}`),
+	}
+
+	_, diags := Upgrade(sources)
+	require.True(t, diags.HasErrors())
+
+	var found bool
+	for _, d := range diags {
+		if strings.Contains(d.Summary, "Ambiguous") {
+			found = true
+		}
+	}
+	require.True(t, found, "expected an ambiguous-reference diagnostic")
+}
+
+func TestUpgrade_TemplateRefsRewritten(t *testing.T) {
+	sources := map[string][]byte{
+		"variables.hcl": []byte(`variable "greeting" {
+  type = string
+}
+`),
+		"template.nomad.tpl": []byte(`# {{ .example.greeting }} looks like a ref but is a comment
+job "x" {
+  value = "{{ .example.greeting }}"
+  nested = "{{ .example.greeting.sub }}"
+}
+`),
+	}
+
+	out, diags := Upgrade(sources)
+	require.False(t, diags.HasErrors())
+
+	got := string(out["template.nomad.tpl"])
+	require.Contains(t, got, `{{ var "greeting" "example" }}`)
+	require.Contains(t, got, `{{ (var "greeting" "example").sub }}`)
+}
+
+func TestUpgrade_RootPackNameCollisionIsReported(t *testing.T) {
+	// The root pack is really named "example" (declared via its own `pack`
+	// metadata block), and an unrelated dependency pack happens to share
+	// that name. A V1 template referencing `.example.greeting` is
+	// ambiguous - it could mean the root's own "greeting" or the
+	// dependency's - and must be reported rather than silently resolved to
+	// whichever one the dependency-graph bookkeeping happens to key on.
+	sources := map[string][]byte{
+		"metadata.hcl": []byte(`pack {
+  name = "example"
+}
+`),
+		"variables.hcl": []byte(`variable "greeting" {
+  type = string
+}
+`),
+		"deps/example/variables.hcl": []byte(`variable "greeting" {
+  type = string
+}
+`),
+		"template.nomad.tpl": []byte(`{{ .example.greeting }}`),
+	}
+
+	_, diags := Upgrade(sources)
+	require.True(t, diags.HasErrors())
+
+	var found bool
+	for _, d := range diags {
+		if strings.Contains(d.Summary, "Ambiguous") {
+			found = true
+		}
+	}
+	require.True(t, found, "expected an ambiguous-reference diagnostic")
+}
+
+func TestFindV1TemplateRefs_IgnoresTextOutsideActions(t *testing.T) {
+	src := `This sentence about file.ext and pkg.json is not a template action.
+{{ .example.greeting }}`
+
+	refs := findV1TemplateRefs("test.tpl", src)
+	require.Len(t, refs, 1)
+	require.Equal(t, "example", refs[0].PackName)
+	require.Equal(t, "greeting", refs[0].VarName)
+}
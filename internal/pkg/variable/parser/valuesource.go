@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ValueSource resolves a variable's value from somewhere outside the pack's
+// own HCL: the environment, a file on disk, a subprocess, or a secrets
+// store such as Vault. Resolution runs after V1/V2 parsing but before
+// ToPackTemplateContext, so a resolved value is indistinguishable from one
+// set directly in a varfile by the time it reaches rendering.
+type ValueSource interface {
+	// Scheme is the `source = "<scheme>:..."` prefix this source handles,
+	// e.g. "env", "file", "exec", or "vault".
+	Scheme() string
+
+	// Resolve returns the value addressed by ref, the part of the source
+	// string after "<scheme>:". Sources that always deal in secrets (e.g.
+	// Vault) should return sensitive=true regardless of the variable's own
+	// declaration.
+	Resolve(ref string) (value string, sensitive bool, err error)
+}
+
+// valueSourceRegistry holds the built-in and any third-party-registered
+// ValueSources, keyed by scheme.
+var valueSourceRegistry = map[string]ValueSource{}
+
+// RegisterValueSource adds a ValueSource to the registry consulted by
+// ResolveValueSources. Built-in sources (env, file, exec, vault) register
+// themselves this way too, so third parties can add their own schemes using
+// the exact same hook.
+func RegisterValueSource(s ValueSource) {
+	valueSourceRegistry[s.Scheme()] = s
+}
+
+func init() {
+	RegisterValueSource(envValueSource{})
+	RegisterValueSource(fileValueSource{})
+	RegisterValueSource(execValueSource{})
+	RegisterValueSource(vaultValueSource{})
+}
+
+// sourceRef is a parsed `source = "scheme:ref"` annotation.
+type sourceRef struct {
+	Scheme string
+	Ref    string
+}
+
+// parseSourceRef splits a `source` attribute value into its scheme and
+// reference, e.g. "vault:secret/data/foo#password" -> ("vault",
+// "secret/data/foo#password").
+func parseSourceRef(raw string) (sourceRef, error) {
+	scheme, ref, ok := strings.Cut(raw, ":")
+	if !ok {
+		return sourceRef{}, fmt.Errorf("malformed source reference %q: expected \"scheme:ref\"", raw)
+	}
+	return sourceRef{Scheme: scheme, Ref: ref}, nil
+}
+
+// ResolveValueSources walks every v2 variable with an unset value and a
+// `source` annotation and populates its Value from the registered
+// ValueSource for that scheme. It runs after V1/V2 parsing and before
+// ToPackTemplateContext, so CLI flags and varfiles - which are merged into
+// variable.Value earlier in the pipeline - always take precedence over a
+// source. Once resolved, v.Source is set to the scheme name (e.g. "env"),
+// matching the provenance values `nomad-pack inspect` reports for every
+// other kind of variable.
+func (pv *ParsedVariables) ResolveValueSources() hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	for _, vars := range pv.v2Vars {
+		for _, v := range vars {
+			if v.Value != cty.NilVal || v.SourceRef == "" {
+				continue
+			}
+
+			ref, err := parseSourceRef(v.SourceRef)
+			if err != nil {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid variable source",
+					Detail:   err.Error(),
+					Subject:  v.DeclRange.Ptr(),
+				})
+				continue
+			}
+
+			source, ok := valueSourceRegistry[ref.Scheme]
+			if !ok {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Unknown variable source",
+					Detail:   fmt.Sprintf("no value source is registered for scheme %q", ref.Scheme),
+					Subject:  v.DeclRange.Ptr(),
+				})
+				continue
+			}
+
+			resolved, sensitive, err := source.Resolve(ref.Ref)
+			if err != nil {
+				diags = diags.Append(&hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  fmt.Sprintf("Failed to resolve %q source", ref.Scheme),
+					Detail:   err.Error(),
+					Subject:  v.DeclRange.Ptr(),
+				})
+				continue
+			}
+
+			v.Value = cty.StringVal(resolved)
+			v.Source = ref.Scheme
+			if sensitive {
+				v.Sensitive = true
+			}
+		}
+	}
+
+	return diags
+}
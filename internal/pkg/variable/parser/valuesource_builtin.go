@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package parser
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// envValueSource resolves `source = "env:NAME"` from the process
+// environment.
+type envValueSource struct{}
+
+func (envValueSource) Scheme() string { return "env" }
+
+func (envValueSource) Resolve(ref string) (string, bool, error) {
+	val, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", false, fmt.Errorf("environment variable %q is not set", ref)
+	}
+	return val, false, nil
+}
+
+// fileValueSource resolves `source = "file:/path/to/value"` by reading the
+// file's contents, trimming a single trailing newline the way most
+// secret-mount sidecars write it.
+type fileValueSource struct{}
+
+func (fileValueSource) Scheme() string { return "file" }
+
+func (fileValueSource) Resolve(ref string) (string, bool, error) {
+	b, err := os.ReadFile(ref)
+	if err != nil {
+		return "", false, fmt.Errorf("reading %q: %w", ref, err)
+	}
+	return strings.TrimSuffix(string(b), "\n"), false, nil
+}
+
+// execValueSource resolves `source = "exec:command --with args"` by
+// running the command through the shell and using its trimmed stdout.
+type execValueSource struct{}
+
+func (execValueSource) Scheme() string { return "exec" }
+
+func (execValueSource) Resolve(ref string) (string, bool, error) {
+	cmd := exec.Command("sh", "-c", ref)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", false, fmt.Errorf("running %q: %w", ref, err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), false, nil
+}
+
+// vaultValueSource resolves `source = "vault:secret/data/foo#password"` by
+// reading a KV secret from Vault using the ambient VAULT_ADDR/VAULT_TOKEN
+// environment, the same convention the Vault CLI and Packer's HCL2 Vault
+// function use. Values sourced from Vault are always treated as sensitive,
+// regardless of how the variable itself was declared.
+type vaultValueSource struct{}
+
+func (vaultValueSource) Scheme() string { return "vault" }
+
+func (vaultValueSource) Resolve(ref string) (string, bool, error) {
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok {
+		return "", true, fmt.Errorf("malformed vault reference %q: expected \"path#key\"", ref)
+	}
+
+	client, err := newVaultClient()
+	if err != nil {
+		return "", true, err
+	}
+
+	secret, err := client.read(path)
+	if err != nil {
+		return "", true, fmt.Errorf("reading vault secret %q: %w", path, err)
+	}
+
+	val, ok := secret[key]
+	if !ok {
+		return "", true, fmt.Errorf("vault secret %q has no key %q", path, key)
+	}
+
+	return val, true, nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/hashicorp/nomad-pack/sdk/pack/variables"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestEnvValueSource(t *testing.T) {
+	t.Setenv("NOMAD_PACK_TEST_VALUE", "from-env")
+
+	val, sensitive, err := envValueSource{}.Resolve("NOMAD_PACK_TEST_VALUE")
+	require.NoError(t, err)
+	require.False(t, sensitive)
+	require.Equal(t, "from-env", val)
+
+	_, _, err = envValueSource{}.Resolve("NOMAD_PACK_TEST_VALUE_UNSET")
+	require.Error(t, err)
+}
+
+func TestFileValueSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "value")
+	require.NoError(t, writeTestFile(path, "from-file\n"))
+
+	val, sensitive, err := fileValueSource{}.Resolve(path)
+	require.NoError(t, err)
+	require.False(t, sensitive)
+	require.Equal(t, "from-file", val)
+}
+
+func TestResolveValueSources(t *testing.T) {
+	t.Setenv("NOMAD_PACK_TEST_VALUE", "from-env")
+
+	pv := &ParsedVariables{
+		v2Vars: map[pack.ID]map[variables.ID]*variables.Variable{
+			"foo": {
+				"token": {
+					Name:      "token",
+					Type:      cty.String,
+					Value:     cty.NilVal,
+					SourceRef: "env:NOMAD_PACK_TEST_VALUE",
+				},
+			},
+		},
+	}
+
+	diags := pv.ResolveValueSources()
+	require.False(t, diags.HasErrors())
+	require.Equal(t, cty.StringVal("from-env"), pv.v2Vars["foo"]["token"].Value)
+	require.Equal(t, SourceEnv, pv.v2Vars["foo"]["token"].Source)
+}
+
+func writeTestFile(path, contents string) error {
+	return os.WriteFile(path, []byte(contents), 0o644)
+}
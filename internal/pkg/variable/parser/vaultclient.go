@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package parser
+
+import (
+	"fmt"
+
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+// vaultClient is the minimal surface vaultValueSource needs from the Vault
+// API client. vaultValueSource builds one directly via newVaultClient.
+type vaultClient struct {
+	logical *vaultapi.Logical
+}
+
+// newVaultClient builds a vaultClient from the ambient VAULT_ADDR/
+// VAULT_TOKEN environment, matching how the Vault CLI and other HashiCorp
+// tooling locate a Vault cluster by default.
+func newVaultClient() (*vaultClient, error) {
+	cfg := vaultapi.DefaultConfig()
+	if err := cfg.ReadEnvironment(); err != nil {
+		return nil, fmt.Errorf("reading Vault environment config: %w", err)
+	}
+
+	client, err := vaultapi.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating Vault client: %w", err)
+	}
+
+	return &vaultClient{logical: client.Logical()}, nil
+}
+
+// read fetches a KV secret and flattens its data into a string map. It
+// supports both KV v1 (data directly under Data) and KV v2 (data nested
+// under Data["data"]) mounts.
+func (c *vaultClient) read(path string) (map[string]string, error) {
+	secret, err := c.logical.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("no secret found at %q", path)
+	}
+
+	data := secret.Data
+	if nested, ok := secret.Data["data"].(map[string]any); ok {
+		data = nested
+	}
+
+	out := make(map[string]string, len(data))
+	for k, v := range data {
+		out[k] = fmt.Sprintf("%v", v)
+	}
+	return out, nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package parser
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+)
+
+// CheckRequiredVersions walks p and its dependencies, verifying that
+// packVersion satisfies each pack's `required_version` constraint and that
+// nomadVersion satisfies each pack's `required_nomad` constraint.
+// A dependency declaring a stricter constraint than its parent fails the
+// whole render, since rendering it would otherwise silently produce a job
+// spec the dependency never intended to support.
+func (pv *ParsedVariables) CheckRequiredVersions(p *pack.Pack, packVersion, nomadVersion string) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	diags = diags.Extend(checkRequiredVersionsR(p, packVersion, nomadVersion))
+	return diags
+}
+
+func checkRequiredVersionsR(p *pack.Pack, packVersion, nomadVersion string) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	diags = diags.Extend(checkOwnRequiredVersions(p, packVersion, nomadVersion))
+
+	for _, d := range p.Dependencies() {
+		diags = diags.Extend(checkRequiredVersionsR(d, packVersion, nomadVersion))
+	}
+
+	return diags
+}
+
+// checkOwnRequiredVersions validates p's own `required_version`/
+// `required_nomad` constraints, without recursing into its dependencies.
+// It's split out from checkRequiredVersionsR so callers that already walk
+// the dependency tree themselves (e.g. toPackTemplateContextR) can check a
+// single pack without re-validating its whole subtree a second time.
+func checkOwnRequiredVersions(p *pack.Pack, packVersion, nomadVersion string) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	diags = diags.Extend(checkConstraint(p, "nomad-pack", packVersion, p.Metadata.RequiredVersion()))
+	diags = diags.Extend(checkConstraint(p, "Nomad", nomadVersion, p.Metadata.RequiredNomad()))
+
+	return diags
+}
+
+// declRange returns subject.Metadata's source range for attaching a
+// diagnostic, or nil if subject has no Metadata (e.g. a pack with no
+// `pack { ... }` block), since a nil *Metadata is a valid pack.Pack per
+// pack.Pack.ID.
+func declRange(subject *pack.Pack) *hcl.Range {
+	if subject.Metadata == nil {
+		return nil
+	}
+	return subject.Metadata.DeclRange.Ptr()
+}
+
+// checkConstraint validates actual against the given go-version.Constraints
+// string, scoped to subject's pack.Metadata for diagnostic source range. An
+// empty constraint string means the pack declared no requirement.
+func checkConstraint(subject *pack.Pack, what, actual, constraintStr string) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+
+	if constraintStr == "" {
+		return diags
+	}
+	// An empty actual version means the caller doesn't know it yet (e.g. no
+	// Nomad client is available to ask). Skip rather than erroring, since a
+	// missing check is better than a false failure.
+	if actual == "" {
+		return diags
+	}
+
+	constraints, err := version.NewConstraint(constraintStr)
+	if err != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Invalid %s version constraint", what),
+			Detail:   fmt.Sprintf("pack %q declares an invalid constraint %q: %s", subject.ID(), constraintStr, err),
+			Subject:  declRange(subject),
+		})
+		return diags
+	}
+
+	actualVer, err := version.NewVersion(actual)
+	if err != nil {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Invalid %s version", what),
+			Detail:   fmt.Sprintf("could not parse %q as a version: %s", actual, err),
+			Subject:  declRange(subject),
+		})
+		return diags
+	}
+
+	if !constraints.Check(actualVer) {
+		diags = diags.Append(&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("Unsupported %s version", what),
+			Detail: fmt.Sprintf(
+				"pack %q requires %s %s, but this is %s %s",
+				subject.ID(), what, constraintStr, what, actual,
+			),
+			Subject: declRange(subject),
+		})
+	}
+
+	return diags
+}
+
+// FormatRequiredVersionsLine renders the `required_version`/
+// `required_nomad` constraints declared on meta as a single human-readable
+// line, for use in `nomad-pack info` output. It returns "" if neither
+// constraint is declared.
+func FormatRequiredVersionsLine(meta *pack.Metadata) string {
+	packConstraint := meta.RequiredVersion()
+	nomadConstraint := meta.RequiredNomad()
+
+	switch {
+	case packConstraint == "" && nomadConstraint == "":
+		return ""
+	case nomadConstraint == "":
+		return fmt.Sprintf("Requires nomad-pack %s", packConstraint)
+	case packConstraint == "":
+		return fmt.Sprintf("Requires Nomad %s", nomadConstraint)
+	default:
+		return fmt.Sprintf("Requires nomad-pack %s, Nomad %s", packConstraint, nomadConstraint)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package parser
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/stretchr/testify/require"
+)
+
+// requiredVersionsTestBody parses src as the body of a `pack { ... }` block
+// for use with Metadata.DecodeRequiredVersions in tests.
+func requiredVersionsTestBody(t *testing.T, src string) *hclsyntax.Body {
+	t.Helper()
+	f, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+	return f.Body.(*hclsyntax.Body)
+}
+
+func TestCheckConstraint(t *testing.T) {
+	p := &pack.Pack{Metadata: &pack.Metadata{}}
+
+	t.Run("satisfied", func(t *testing.T) {
+		diags := checkConstraint(p, "nomad-pack", "0.2.0", ">= 0.1.0")
+		require.False(t, diags.HasErrors())
+	})
+
+	t.Run("violated", func(t *testing.T) {
+		diags := checkConstraint(p, "nomad-pack", "0.0.9", ">= 0.1.0")
+		require.True(t, diags.HasErrors())
+	})
+
+	t.Run("no constraint declared", func(t *testing.T) {
+		diags := checkConstraint(p, "nomad-pack", "0.0.1", "")
+		require.False(t, diags.HasErrors())
+	})
+
+	t.Run("invalid constraint", func(t *testing.T) {
+		diags := checkConstraint(p, "nomad-pack", "0.2.0", "not-a-constraint")
+		require.True(t, diags.HasErrors())
+	})
+
+	t.Run("unknown actual version is skipped, not an error", func(t *testing.T) {
+		diags := checkConstraint(p, "Nomad", "", ">= 1.6")
+		require.False(t, diags.HasErrors())
+	})
+}
+
+func TestCheckConstraint_NilMetadata(t *testing.T) {
+	// A pack.Pack with a nil Metadata is valid input (pack.Pack.ID treats it
+	// the same way), so checking its constraints must not panic even though
+	// it has no `pack { ... }` block to read a constraint or DeclRange from.
+	p := &pack.Pack{}
+	diags := checkOwnRequiredVersions(p, "0.2.0", "1.6.0")
+	require.False(t, diags.HasErrors())
+}
+
+func TestLoadV2Result_ValidatesRequiredVersionAtParseTime(t *testing.T) {
+	meta := &pack.Metadata{}
+	decodeDiags := meta.DecodeRequiredVersions(requiredVersionsTestBody(t, `required_version = ">= 100.0.0"`))
+	require.False(t, decodeDiags.HasErrors(), decodeDiags.Error())
+
+	pv := &ParsedVariables{Metadata: meta}
+	err := pv.LoadV2Result(nil)
+	require.Error(t, err)
+}
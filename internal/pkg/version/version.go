@@ -0,0 +1,10 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package version holds the nomad-pack binary's own version, set via
+// linker flags at build time.
+package version
+
+// Version is the nomad-pack release version. It defaults to a dev marker
+// here; release builds override it with `-ldflags -X`.
+var Version = "0.0.1-dev"
@@ -0,0 +1,160 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package pack
+
+import (
+	goversion "github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+)
+
+// Metadata is a pack's `pack { ... }` metadata block.
+type Metadata struct {
+	PackMeta *MetadataPack
+	App      *MetadataApp
+
+	// RequiredVersions holds the `required_version`/`required_nomad`
+	// constraints declared in this pack's `pack` block, parsed by
+	// DecodeRequiredVersions. It is nil if the pack declared neither.
+	RequiredVersions *RequiredVersions
+
+	DeclRange hcl.Range
+}
+
+// MetadataPack is the `pack { name = ... }` portion of a pack's metadata.
+type MetadataPack struct {
+	Name        string
+	Description string
+	Version     string
+}
+
+// MetadataApp is the `app { url = ... }` portion of a pack's metadata.
+type MetadataApp struct {
+	URL string
+}
+
+// RequiredVersions holds the parsed `required_version`/`required_nomad`
+// constraints from a pack's `pack` block, mirroring how Terraform and
+// Packer attach a go-version.Constraints to their own required_version.
+type RequiredVersions struct {
+	Pack  goversion.Constraints
+	Nomad goversion.Constraints
+
+	// raw preserves the original constraint strings for diagnostics and
+	// `nomad-pack info` output, since go-version.Constraints doesn't round
+	// trip back to its source text.
+	rawPack  string
+	rawNomad string
+}
+
+// requiredVersionsSchema describes the `required_version`/`required_nomad`
+// attributes inside a `pack { ... }` block.
+var requiredVersionsSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "required_version"},
+		{Name: "required_nomad"},
+	},
+}
+
+// DecodeRequiredVersions parses the `required_version`/`required_nomad`
+// attributes out of a pack's `pack { ... }` block body and populates
+// m.RequiredVersions. It is a no-op, successfully, if neither attribute is
+// present.
+func (m *Metadata) DecodeRequiredVersions(body hcl.Body) hcl.Diagnostics {
+	content, _, diags := body.PartialContent(requiredVersionsSchema)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	rv := &RequiredVersions{}
+	hasAny := false
+
+	if attr, ok := content.Attributes["required_version"]; ok {
+		var raw string
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &raw)...)
+		if !diags.HasErrors() {
+			c, err := goversion.NewConstraint(raw)
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid required_version constraint",
+					Detail:   err.Error(),
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			} else {
+				rv.Pack = c
+				rv.rawPack = raw
+				hasAny = true
+			}
+		}
+	}
+
+	if attr, ok := content.Attributes["required_nomad"]; ok {
+		var raw string
+		diags = append(diags, gohcl.DecodeExpression(attr.Expr, nil, &raw)...)
+		if !diags.HasErrors() {
+			c, err := goversion.NewConstraint(raw)
+			if err != nil {
+				diags = append(diags, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  "Invalid required_nomad constraint",
+					Detail:   err.Error(),
+					Subject:  attr.Expr.Range().Ptr(),
+				})
+			} else {
+				rv.Nomad = c
+				rv.rawNomad = raw
+				hasAny = true
+			}
+		}
+	}
+
+	if hasAny {
+		m.RequiredVersions = rv
+	}
+
+	return diags
+}
+
+// RequiredVersion returns this pack's declared `required_version`
+// constraint string, or "" if it didn't declare one. A nil receiver
+// returns "", since a pack without a `pack { ... }` block is treated the
+// same as one that declares no constraint (see pack.Pack.ID).
+func (m *Metadata) RequiredVersion() string {
+	if m == nil || m.RequiredVersions == nil {
+		return ""
+	}
+	return m.RequiredVersions.rawPack
+}
+
+// RequiredNomad returns this pack's declared `required_nomad` constraint
+// string, or "" if it didn't declare one. A nil receiver returns "", for
+// the same reason as RequiredVersion.
+func (m *Metadata) RequiredNomad() string {
+	if m == nil || m.RequiredVersions == nil {
+		return ""
+	}
+	return m.RequiredVersions.rawNomad
+}
+
+// ConvertToMapInterface flattens this metadata into the map consumed by
+// the `.nomad_pack.meta` template accessor. A nil receiver returns an
+// empty map, for the same reason as RequiredVersion.
+func (m *Metadata) ConvertToMapInterface() map[string]any {
+	out := make(map[string]any)
+	if m == nil {
+		return out
+	}
+	if m.PackMeta != nil {
+		out["pack"] = map[string]any{
+			"name":        m.PackMeta.Name,
+			"description": m.PackMeta.Description,
+			"version":     m.PackMeta.Version,
+		}
+	}
+	if m.App != nil {
+		out["app"] = map[string]any{"url": m.App.URL}
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package pack holds the types describing a parsed pack and its metadata,
+// shared across the variable parsers, the renderer, and the CLI.
+package pack
+
+// ID identifies a pack within a render, either the root pack or one of its
+// dependencies. Pack.ID yields the pack's own name; no dotted dependency
+// path (e.g. "foo.bar") is built from its parents.
+type ID string
+
+// Pack is a single parsed pack, either the root pack being rendered or one
+// of its (transitive) dependencies.
+type Pack struct {
+	Metadata *Metadata
+
+	name         string
+	alias        string
+	dependencies []*Pack
+}
+
+// ID returns this pack's simple name, as declared in its `pack { name = "..." }`
+// metadata block.
+func (p *Pack) ID() ID {
+	if p.Metadata != nil && p.Metadata.PackMeta != nil {
+		return ID(p.Metadata.PackMeta.Name)
+	}
+	return ID(p.name)
+}
+
+// VariablesPath returns the key used for this pack's variables in
+// parser.ParsedVariables's v2 maps. It is the same as ID: the pack's own
+// name, for the root and for a dependency alike; no "<parent>.<child>"
+// prefix is added, so dependencies that share a name share a key.
+func (p *Pack) VariablesPath() ID {
+	return p.ID()
+}
+
+// Dependencies returns this pack's direct dependency packs.
+func (p *Pack) Dependencies() []*Pack {
+	return p.dependencies
+}
+
+// AliasOrName returns the alias this pack was given by its parent's
+// `dependency` block, or its own name if it wasn't aliased.
+func (p *Pack) AliasOrName() ID {
+	if p.alias != "" {
+		return ID(p.alias)
+	}
+	return p.ID()
+}
@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package variables holds the types describing a pack's variables, shared
+// by both the V1 and V2 variable parsers.
+package variables
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/nomad-pack/sdk/pack"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ID identifies a single variable within a pack.
+type ID string
+
+// Variable represents a single pack variable, whether declared via a
+// `variable` block (V1/V2) or a `locals` block (V2 only, in which case Expr
+// is set instead of Value being populated directly from a default/CLI/
+// varfile merge).
+type Variable struct {
+	Name        string
+	Type        cty.Type
+	Value       cty.Value
+	Default     cty.Value
+	Description string
+	DeclRange   hcl.Range
+
+	// Expr holds a local's expression, evaluated by
+	// parser.ParsedVariables.EvaluateLocals. It is nil for ordinary
+	// variables, whose Value is populated directly.
+	Expr hcl.Expression
+
+	// Sensitive marks a variable declared `sensitive = true`. Sensitive
+	// values are redacted from generated varfiles and inspection output,
+	// but still flow through to template rendering at their real value.
+	Sensitive bool
+
+	// SourceRef is the raw `source = "scheme:ref"` annotation as written by
+	// the pack author, e.g. "vault:secret/data/foo#password". It is only
+	// set when the variable's value is meant to come from an external
+	// ValueSource rather than a default/varfile/CLI flag.
+	SourceRef string
+
+	// Source reports where this variable's effective value came from:
+	// "default", "file", "cli", "env", "exec", or "vault". It is set as the
+	// variable is merged and resolved, and is purely informational - it
+	// drives `nomad-pack inspect` output, not rendering.
+	Source string
+}
+
+// AsOverrideString renders this variable as a single line of a V2
+// `<pack>.<name> = <value>` override file, the format `generate varfile`
+// produces.
+func (v *Variable) AsOverrideString(packName pack.ID) string {
+	return fmt.Sprintf("%s.%s = %s\n", packName, v.Name, hclEncode(v.Value))
+}
+
+// hclEncode renders a cty.Value as an HCL literal suitable for an override
+// file. Strings are quoted; everything else uses its natural HCL
+// representation.
+func hclEncode(val cty.Value) string {
+	if val == cty.NilVal || !val.IsKnown() || val.IsNull() {
+		return "null"
+	}
+	if val.Type() == cty.String {
+		return fmt.Sprintf("%q", val.AsString())
+	}
+	return val.GoString()
+}
+
+// ConvertCtyToInterface converts a cty.Value into its native Go
+// representation, as used when building the template rendering context and
+// `nomad-pack inspect` output.
+func ConvertCtyToInterface(val cty.Value) (any, error) {
+	if val == cty.NilVal || val.IsNull() {
+		return nil, nil
+	}
+	if !val.IsWhollyKnown() {
+		return nil, fmt.Errorf("value is not fully known")
+	}
+
+	t := val.Type()
+	switch {
+	case t == cty.String:
+		return val.AsString(), nil
+	case t == cty.Bool:
+		return val.True(), nil
+	case t == cty.Number:
+		f, _ := val.AsBigFloat().Float64()
+		return f, nil
+	case t.IsListType(), t.IsSetType(), t.IsTupleType():
+		var out []any
+		for it := val.ElementIterator(); it.Next(); {
+			_, ev := it.Element()
+			cv, err := ConvertCtyToInterface(ev)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, cv)
+		}
+		return out, nil
+	case t.IsMapType(), t.IsObjectType():
+		out := make(map[string]any)
+		for it := val.ElementIterator(); it.Next(); {
+			kv, ev := it.Element()
+			cv, err := ConvertCtyToInterface(ev)
+			if err != nil {
+				return nil, err
+			}
+			out[kv.AsString()] = cv
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported variable type %s", t.FriendlyName())
+	}
+}